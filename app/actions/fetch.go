@@ -0,0 +1,17 @@
+package actions
+
+import (
+	"github.com/parnurzeal/gorequest"
+
+	"mq-subscriber/app/types"
+)
+
+func Fetch(option types.FetchOption) ([]byte, []error) {
+	_, body, errs := gorequest.New().
+		Post(option.Url).
+		Set("X-Webhook-Secret", option.Secret).
+		Set("Content-Type", "application/json").
+		Send(option.Body).
+		EndBytes()
+	return body, errs
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	pb "mq-subscriber/router"
+	"mq-subscriber/app/mq"
+)
+
+func (c *controller) Publish(ctx context.Context, param *pb.PublishParameter) (*pb.Response, error) {
+	headers := make(map[string]interface{}, len(param.Headers))
+	for key, value := range param.Headers {
+		headers[key] = value
+	}
+	timeout := time.Duration(param.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	err := c.manager.Publish(param.Identity, mq.PublishOption{
+		Exchange:    param.Exchange,
+		RoutingKey:  param.RoutingKey,
+		ContentType: param.ContentType,
+		Headers:     headers,
+		Body:        param.Body,
+		Mandatory:   param.Mandatory,
+		Immediate:   param.Immediate,
+	}, param.Confirm, timeout)
+	if err != nil {
+		return c.response(err)
+	}
+	return c.response(nil)
+}
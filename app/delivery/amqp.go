@@ -0,0 +1,34 @@
+package delivery
+
+import (
+	"time"
+
+	"mq-subscriber/app/mq"
+	"mq-subscriber/app/types"
+)
+
+type AMQPDeliverer struct {
+	emitting    mq.Emitting
+	confirmMode bool
+	timeout     time.Duration
+}
+
+func NewAMQPDeliverer(emitting mq.Emitting, confirmMode bool, timeout time.Duration) *AMQPDeliverer {
+	return &AMQPDeliverer{emitting: emitting, confirmMode: confirmMode, timeout: timeout}
+}
+
+func (d *AMQPDeliverer) Deliver(option types.SubscriberOption, body []byte) ([]byte, []error) {
+	emitter, err := d.emitting.Emitter(d.confirmMode)
+	if err != nil {
+		return nil, []error{err}
+	}
+	defer emitter.Close()
+	if err := emitter.Publish(mq.PublishOption{
+		Exchange:   option.RepublishExchange,
+		RoutingKey: option.RepublishRoutingKey,
+		Body:       body,
+	}, d.timeout); err != nil {
+		return nil, []error{err}
+	}
+	return nil, nil
+}
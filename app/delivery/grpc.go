@@ -0,0 +1,87 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+
+	"mq-subscriber/app/types"
+)
+
+func init() {
+	encoding.RegisterCodec(rawCodec{})
+}
+
+type rawCodec struct{}
+
+func (rawCodec) Name() string {
+	return "raw"
+}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	if body, ok := v.([]byte); ok {
+		return body, nil
+	}
+	return nil, fmt.Errorf("delivery: unsupported payload type %T", v)
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	body, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("delivery: unsupported payload type %T", v)
+	}
+	*body = data
+	return nil
+}
+
+type GRPCDeliverer struct {
+	timeout time.Duration
+
+	mutex sync.RWMutex
+	conns map[string]*grpc.ClientConn
+}
+
+func NewGRPCDeliverer(timeout time.Duration) *GRPCDeliverer {
+	return &GRPCDeliverer{
+		timeout: timeout,
+		conns:   make(map[string]*grpc.ClientConn),
+	}
+}
+
+func (d *GRPCDeliverer) conn(target string) (*grpc.ClientConn, error) {
+	d.mutex.RLock()
+	conn, ok := d.conns[target]
+	d.mutex.RUnlock()
+	if ok {
+		return conn, nil
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if conn, ok := d.conns[target]; ok {
+		return conn, nil
+	}
+	conn, err := grpc.Dial(target, grpc.WithInsecure(), grpc.WithDefaultCallOptions(grpc.CallContentSubtype("raw")))
+	if err != nil {
+		return nil, err
+	}
+	d.conns[target] = conn
+	return conn, nil
+}
+
+func (d *GRPCDeliverer) Deliver(option types.SubscriberOption, body []byte) ([]byte, []error) {
+	conn, err := d.conn(option.GRPCTarget)
+	if err != nil {
+		return nil, []error{err}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	var response []byte
+	if err := conn.Invoke(ctx, option.GRPCMethod, body, &response); err != nil {
+		return nil, []error{err}
+	}
+	return response, nil
+}
@@ -0,0 +1,20 @@
+package delivery
+
+import (
+	"mq-subscriber/app/actions"
+	"mq-subscriber/app/types"
+)
+
+type HTTPDeliverer struct{}
+
+func NewHTTPDeliverer() *HTTPDeliverer {
+	return &HTTPDeliverer{}
+}
+
+func (d *HTTPDeliverer) Deliver(option types.SubscriberOption, body []byte) ([]byte, []error) {
+	return actions.Fetch(types.FetchOption{
+		Url:    option.Url,
+		Secret: option.Secret,
+		Body:   string(body),
+	})
+}
@@ -0,0 +1,29 @@
+package logging
+
+import (
+	jsoniter "github.com/json-iterator/go"
+	"go.uber.org/zap"
+
+	"mq-subscriber/app/types"
+)
+
+type Logging struct {
+	logger *zap.Logger
+}
+
+func NewLogging(logger *zap.Logger) *Logging {
+	return &Logging{logger: logger}
+}
+
+func (l *Logging) Push(push *types.LoggingPush) {
+	body, err := jsoniter.Marshal(push.Message)
+	if err != nil {
+		l.logger.Error("logging: failed to marshal message", zap.String("identity", push.Identity), zap.Error(err))
+		return
+	}
+	if push.HasError {
+		l.logger.Error("delivery failed", zap.String("identity", push.Identity), zap.ByteString("message", body))
+		return
+	}
+	l.logger.Info("delivery succeeded", zap.String("identity", push.Identity), zap.ByteString("message", body))
+}
@@ -0,0 +1,125 @@
+package manager
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"mq-subscriber/app/logging"
+	"mq-subscriber/app/mq"
+	"mq-subscriber/app/schema"
+	"mq-subscriber/app/types"
+)
+
+type Manager struct {
+	schema     *schema.Schema
+	logging    *logging.Logging
+	deliverers map[string]types.Deliverer
+	mutex      sync.RWMutex
+	brokers    map[string]mq.Broker
+}
+
+func NewManager(schema *schema.Schema, logging *logging.Logging, deliverers map[string]types.Deliverer) *Manager {
+	return &Manager{
+		schema:     schema,
+		logging:    logging,
+		deliverers: deliverers,
+		brokers:    make(map[string]mq.Broker),
+	}
+}
+
+func (m *Manager) broker(source string) (mq.Broker, error) {
+	m.mutex.RLock()
+	broker, ok := m.brokers[source]
+	m.mutex.RUnlock()
+	if ok {
+		return broker, nil
+	}
+
+	candidate, err := mq.New(source, m.schema, m.logging)
+	if err != nil {
+		return nil, err
+	}
+	if delivering, ok := candidate.(mq.Delivering); ok {
+		delivering.SetDeliverers(m.deliverers)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if broker, ok := m.brokers[source]; ok {
+		if closer, ok := candidate.(mq.Closer); ok {
+			if err := closer.Close(); err != nil {
+				logrus.Error("manager: failed to close discarded broker for ", source, ": ", err)
+			}
+		}
+		return broker, nil
+	}
+	m.brokers[source] = candidate
+	return candidate, nil
+}
+
+func (m *Manager) Create(option types.SubscriberOption) error {
+	broker, err := m.broker(option.Source)
+	if err != nil {
+		return err
+	}
+	if err := m.schema.Set(option); err != nil {
+		return err
+	}
+	if err := broker.SetChannel(option.Identity); err != nil {
+		return err
+	}
+	return broker.SetConsume(option)
+}
+
+func (m *Manager) Health() map[string]bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	health := make(map[string]bool, len(m.brokers))
+	for source, broker := range m.brokers {
+		health[source] = broker.Health()
+	}
+	return health
+}
+
+func (m *Manager) Publish(identity string, option mq.PublishOption, confirmMode bool, timeout time.Duration) error {
+	subscriber, err := m.schema.Get(identity)
+	if err != nil {
+		return err
+	}
+	broker, err := m.broker(subscriber.Source)
+	if err != nil {
+		return err
+	}
+	emitting, ok := broker.(mq.Emitting)
+	if !ok {
+		return fmt.Errorf("manager: broker for %q does not support publishing", subscriber.Source)
+	}
+	emitter, err := emitting.Emitter(confirmMode)
+	if err != nil {
+		return err
+	}
+	defer emitter.Close()
+	return emitter.Publish(option, timeout)
+}
+
+func (m *Manager) Delete(identity string) error {
+	option, err := m.schema.Get(identity)
+	if err != nil {
+		return err
+	}
+	broker, err := m.broker(option.Source)
+	if err != nil {
+		return err
+	}
+	if err := broker.CloseChannel(identity); err != nil {
+		return err
+	}
+	if err := broker.Delete(identity); err != nil {
+		return err
+	}
+	m.schema.Delete(identity)
+	return nil
+}
@@ -1,18 +1,37 @@
 package mq
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+
 	jsoniter "github.com/json-iterator/go"
 	"github.com/sirupsen/logrus"
 	"github.com/streadway/amqp"
 	"github.com/xeipuuv/gojsonschema"
-	"mq-subscriber/app/actions"
+	"golang.org/x/time/rate"
 	"mq-subscriber/app/logging"
 	"mq-subscriber/app/schema"
 	"mq-subscriber/app/types"
 	"mq-subscriber/app/utils"
+	"strconv"
 	"time"
 )
 
+const (
+	retryQueueSuffix = ".retry"
+	deadLetterSuffix = ".dlq"
+
+	defaultBackoffBase       = time.Second
+	defaultBackoffMax        = time.Minute
+	defaultBackoffMultiplier = 2.0
+	defaultMaxAttempts       = 0
+
+	retryBackoffMax = int64(24 * time.Hour / time.Millisecond)
+)
+
 type AmqpDrive struct {
 	url             string
 	schema          *schema.Schema
@@ -23,13 +42,55 @@ type AmqpDrive struct {
 	channelDone     *utils.SyncChannelDone
 	channelReady    *utils.SyncChannelReady
 	notifyChanClose *utils.SyncNotifyChanClose
+	workers         *utils.SyncWaitGroup
+	workersCancel   *utils.SyncCancel
+
+	backoffBase       time.Duration
+	backoffMax        time.Duration
+	backoffMultiplier float64
+	maxAttempts       int
+
+	healthMutex sync.RWMutex
+	healthy     bool
+
+	deliverersMutex sync.RWMutex
+	deliverers      map[string]types.Deliverer
+
+	randMutex sync.Mutex
+	rand      *rand.Rand
+}
+
+type Option func(*AmqpDrive)
+
+func WithBackoff(base, max time.Duration, multiplier float64) Option {
+	return func(c *AmqpDrive) {
+		c.backoffBase = base
+		c.backoffMax = max
+		c.backoffMultiplier = multiplier
+	}
 }
 
-func NewAmqpDrive(url string, schema *schema.Schema, logging *logging.Logging) (session *AmqpDrive, err error) {
+func WithMaxAttempts(attempts int) Option {
+	return func(c *AmqpDrive) {
+		c.maxAttempts = attempts
+	}
+}
+
+func NewAmqpDrive(url string, schema *schema.Schema, logging *logging.Logging, options ...Option) (session *AmqpDrive, err error) {
 	session = new(AmqpDrive)
 	session.url = url
 	session.schema = schema
 	session.logging = logging
+	session.backoffBase = defaultBackoffBase
+	session.backoffMax = defaultBackoffMax
+	session.backoffMultiplier = defaultBackoffMultiplier
+	session.maxAttempts = defaultMaxAttempts
+	session.healthy = true
+	session.deliverers = make(map[string]types.Deliverer)
+	session.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, option := range options {
+		option(session)
+	}
 	conn, err := amqp.Dial(url)
 	if err != nil {
 		return
@@ -42,9 +103,48 @@ func NewAmqpDrive(url string, schema *schema.Schema, logging *logging.Logging) (
 	session.channelDone = utils.NewSyncChannelDone()
 	session.channelReady = utils.NewSyncChannelReady()
 	session.notifyChanClose = utils.NewSyncNotifyChanClose()
+	session.workers = utils.NewSyncWaitGroup()
+	session.workersCancel = utils.NewSyncCancel()
 	return
 }
 
+func (c *AmqpDrive) setHealthy(healthy bool) {
+	c.healthMutex.Lock()
+	defer c.healthMutex.Unlock()
+	c.healthy = healthy
+}
+
+func (c *AmqpDrive) Health() bool {
+	c.healthMutex.RLock()
+	defer c.healthMutex.RUnlock()
+	return c.healthy
+}
+
+func (c *AmqpDrive) SetDeliverers(deliverers map[string]types.Deliverer) {
+	c.deliverersMutex.Lock()
+	defer c.deliverersMutex.Unlock()
+	c.deliverers = deliverers
+}
+
+func (c *AmqpDrive) deliverer(transport string) types.Deliverer {
+	c.deliverersMutex.RLock()
+	defer c.deliverersMutex.RUnlock()
+	return pickDeliverer(c.deliverers, transport)
+}
+
+func (c *AmqpDrive) backoff(attempt int) time.Duration {
+	delay := float64(c.backoffBase) * math.Pow(c.backoffMultiplier, float64(attempt))
+	if max := float64(c.backoffMax); delay > max {
+		delay = max
+	}
+	if delay <= 0 {
+		return 0
+	}
+	c.randMutex.Lock()
+	defer c.randMutex.Unlock()
+	return time.Duration(c.rand.Int63n(int64(delay) + 1))
+}
+
 func (c *AmqpDrive) listenConn() {
 	select {
 	case <-c.notifyConnClose:
@@ -54,11 +154,24 @@ func (c *AmqpDrive) listenConn() {
 }
 
 func (c *AmqpDrive) reconnected() {
-	count := 0
+	attempt := 0
 	for {
-		time.Sleep(time.Second * 5)
-		count++
-		logrus.Info("Trying to reconnect:", count)
+		if c.maxAttempts > 0 && attempt >= c.maxAttempts {
+			c.setHealthy(false)
+			c.logging.Push(&types.LoggingPush{
+				Identity: "connection",
+				HasError: true,
+				Message: map[string]interface{}{
+					"event":    "reconnect_exhausted",
+					"attempts": attempt,
+				},
+			})
+			logrus.Error("AMQP reconnect attempts exhausted, giving up")
+			return
+		}
+		time.Sleep(c.backoff(attempt))
+		attempt++
+		logrus.Info("Trying to reconnect:", attempt)
 		conn, err := amqp.Dial(c.url)
 		if err != nil {
 			logrus.Error(err)
@@ -68,6 +181,7 @@ func (c *AmqpDrive) reconnected() {
 		c.notifyConnClose = make(chan *amqp.Error)
 		conn.NotifyClose(c.notifyConnClose)
 		go c.listenConn()
+		c.setHealthy(true)
 		logrus.Info("Attempt to reconnect successfully")
 		break
 	}
@@ -103,18 +217,31 @@ func (c *AmqpDrive) listenChannel(ID string) {
 }
 
 func (c *AmqpDrive) refreshChannel(ID string) {
+	attempt := 0
 	for {
+		if c.maxAttempts > 0 && attempt >= c.maxAttempts {
+			c.channelReady.Set(ID, false)
+			c.setHealthy(false)
+			logrus.Error("Channel refresh attempts exhausted:", ID)
+			return
+		}
 		err := c.SetChannel(ID)
 		if err != nil {
+			time.Sleep(c.backoff(attempt))
+			attempt++
 			continue
 		}
 		option, err := c.schema.Get(ID)
 		if err != nil {
+			time.Sleep(c.backoff(attempt))
+			attempt++
 			continue
 		}
 		err = c.SetConsume(option)
 		if err != nil {
 			if c.channelReady.Get(ID) {
+				time.Sleep(c.backoff(attempt))
+				attempt++
 				continue
 			} else {
 				break
@@ -127,11 +254,205 @@ func (c *AmqpDrive) refreshChannel(ID string) {
 
 func (c *AmqpDrive) CloseChannel(ID string) error {
 	c.channelDone.Get(ID) <- 1
-	return c.channel.Get(ID).Close()
+	channel := c.channel.Get(ID)
+	if err := channel.Cancel(ID, false); err != nil {
+		logrus.Error("failed to cancel consumer:", ID, err)
+	}
+	if cancel := c.workersCancel.Get(ID); cancel != nil {
+		cancel()
+	}
+	if workers := c.workers.Get(ID); workers != nil {
+		workers.Wait()
+	}
+	return channel.Close()
+}
+
+func (c *AmqpDrive) Delete(ID string) error {
+	c.channel.Delete(ID)
+	c.channelDone.Delete(ID)
+	c.channelReady.Delete(ID)
+	c.notifyChanClose.Delete(ID)
+	c.workers.Delete(ID)
+	c.workersCancel.Delete(ID)
+	return nil
+}
+
+func (c *AmqpDrive) Close() error {
+	return c.conn.Close()
+}
+
+func (c *AmqpDrive) declareDeadLetter(option types.SubscriberOption) error {
+	if option.DeadLetterExchange == "" {
+		return nil
+	}
+	channel := c.channel.Get(option.Identity)
+	if err := channel.ExchangeDeclare(option.DeadLetterExchange, "direct", true, false, false, false, nil); err != nil {
+		return err
+	}
+	terminalQueue := option.Queue + deadLetterSuffix
+	if _, err := channel.QueueDeclare(terminalQueue, true, false, false, false, nil); err != nil {
+		return err
+	}
+	if err := channel.QueueBind(terminalQueue, option.DeadLetterRoutingKey, option.DeadLetterExchange, false, nil); err != nil {
+		return err
+	}
+	retryQueue := option.Queue + retryQueueSuffix
+	_, err := channel.QueueDeclare(retryQueue, true, false, false, false, amqp.Table{
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": option.Queue,
+	})
+	return err
+}
+
+func retryCount(headers amqp.Table) int64 {
+	deaths, ok := headers["x-death"].([]interface{})
+	if !ok || len(deaths) == 0 {
+		return 0
+	}
+	death, ok := deaths[0].(amqp.Table)
+	if !ok {
+		return 0
+	}
+	count, _ := death["count"].(int64)
+	return count
+}
+
+func retryBackoff(base int64, attempt int64) string {
+	if base <= 0 {
+		base = 1000
+	}
+	delay := float64(base) * math.Pow(2, float64(attempt))
+	if delay > float64(retryBackoffMax) || math.IsInf(delay, 0) {
+		delay = float64(retryBackoffMax)
+	}
+	return strconv.FormatInt(int64(delay), 10)
+}
+
+func (c *AmqpDrive) reject(option types.SubscriberOption, d amqp.Delivery) {
+	channel := c.channel.Get(option.Identity)
+	if option.DeadLetterExchange == "" {
+		d.Nack(false, false)
+		return
+	}
+	attempt := retryCount(d.Headers)
+	if int(attempt) < option.MaxRetries {
+		err := channel.Publish("", option.Queue+retryQueueSuffix, false, false, amqp.Publishing{
+			Headers:     d.Headers,
+			ContentType: d.ContentType,
+			Body:        d.Body,
+			Expiration:  retryBackoff(option.DeadLetterTTL, attempt),
+		})
+		if err != nil {
+			logrus.Error("failed to republish to retry queue:", err)
+			d.Nack(false, false)
+			return
+		}
+		d.Ack(false)
+		return
+	}
+	err := channel.Publish(option.DeadLetterExchange, option.DeadLetterRoutingKey, false, false, amqp.Publishing{
+		Headers:     d.Headers,
+		ContentType: d.ContentType,
+		Body:        d.Body,
+	})
+	if err != nil {
+		logrus.Error("failed to publish to dead-letter exchange:", err)
+	}
+	d.Ack(false)
+}
+
+func (c *AmqpDrive) process(option types.SubscriberOption, d amqp.Delivery) {
+	var body []byte
+	var errs []error
+	if deliverer := c.deliverer(option.Transport); deliverer != nil {
+		body, errs = deliverer.Deliver(option, d.Body)
+	} else {
+		errs = []error{fmt.Errorf("mq: no deliverer registered for transport %q", option.Transport)}
+	}
+	var message map[string]interface{}
+	var bodyRecord interface{}
+	if jsoniter.Valid(d.Body) {
+		jsoniter.Unmarshal(d.Body, &bodyRecord)
+	} else {
+		c.reject(option, d)
+		return
+	}
+	if len(errs) != 0 {
+		msg := make([]string, len(errs))
+		for index, value := range errs {
+			msg[index] = value.Error()
+		}
+		message = map[string]interface{}{
+			"Identity": option.Identity,
+			"Queue":    option.Queue,
+			"Url":      option.Url,
+			"Secret":   option.Secret,
+			"Body":     bodyRecord,
+			"Status":   false,
+			"Response": map[string]interface{}{
+				"errs": msg,
+			},
+			"Time": time.Now().Unix(),
+		}
+		c.reject(option, d)
+	} else {
+		var responseRecord interface{}
+		result, err := gojsonschema.Validate(
+			gojsonschema.NewBytesLoader([]byte(`{"type":"object"}`)),
+			gojsonschema.NewBytesLoader(body),
+		)
+		if err != nil {
+			responseRecord = map[string]interface{}{
+				"raw": string(body),
+			}
+		} else {
+			if result.Valid() {
+				jsoniter.Unmarshal(body, &responseRecord)
+			} else {
+				responseRecord = map[string]interface{}{
+					"raw": string(body),
+				}
+			}
+		}
+		message = map[string]interface{}{
+			"Identity": option.Identity,
+			"Queue":    option.Queue,
+			"Url":      option.Url,
+			"Secret":   option.Secret,
+			"Body":     bodyRecord,
+			"Status":   true,
+			"Response": responseRecord,
+			"Time":     time.Now().Unix(),
+		}
+		d.Ack(false)
+	}
+	c.logging.Push(&types.LoggingPush{
+		Identity: option.Identity,
+		HasError: len(errs) != 0,
+		Message:  message,
+	})
 }
 
 func (c *AmqpDrive) SetConsume(option types.SubscriberOption) (err error) {
-	msgs, err := c.channel.Get(option.Identity).Consume(
+	if err = c.declareDeadLetter(option); err != nil {
+		return
+	}
+	channel := c.channel.Get(option.Identity)
+	concurrency := option.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	prefetch := option.PrefetchCount
+	if prefetch <= 0 {
+		prefetch = 1
+	}
+	if prefetch < concurrency {
+		prefetch = concurrency
+	}
+	if err = channel.Qos(prefetch, 0, false); err != nil {
+		return
+	}
+	msgs, err := channel.Consume(
 		option.Queue,
 		option.Identity,
 		false,
@@ -145,76 +466,30 @@ func (c *AmqpDrive) SetConsume(option types.SubscriberOption) (err error) {
 		return
 	}
 	c.channelReady.Set(option.Identity, true)
-	go func() {
-		for d := range msgs {
-			body, errs := actions.Fetch(types.FetchOption{
-				Url:    option.Url,
-				Secret: option.Secret,
-				Body:   string(d.Body),
-			})
-			var message map[string]interface{}
-			var bodyRecord interface{}
-			if jsoniter.Valid(d.Body) {
-				jsoniter.Unmarshal(d.Body, &bodyRecord)
-			} else {
-				d.Nack(false, false)
-				return
-			}
-			if len(errs) != 0 {
-				msg := make([]string, len(errs))
-				for index, value := range errs {
-					msg[index] = value.Error()
-				}
-				message = map[string]interface{}{
-					"Identity": option.Identity,
-					"Queue":    option.Queue,
-					"Url":      option.Url,
-					"Secret":   option.Secret,
-					"Body":     bodyRecord,
-					"Status":   false,
-					"Response": map[string]interface{}{
-						"errs": msg,
-					},
-					"Time": time.Now().Unix(),
-				}
-				d.Nack(false, false)
-			} else {
-				var responseRecord interface{}
-				result, err := gojsonschema.Validate(
-					gojsonschema.NewBytesLoader([]byte(`{"type":"object"}`)),
-					gojsonschema.NewBytesLoader(body),
-				)
-				if err != nil {
-					responseRecord = map[string]interface{}{
-						"raw": string(body),
-					}
-				} else {
-					if result.Valid() {
-						jsoniter.Unmarshal(body, &responseRecord)
-					} else {
-						responseRecord = map[string]interface{}{
-							"raw": string(body),
-						}
+
+	var limiter *rate.Limiter
+	if option.RatePerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(option.RatePerSecond), option.RatePerSecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.workersCancel.Set(option.Identity, cancel)
+
+	var workers sync.WaitGroup
+	workers.Add(concurrency)
+	c.workers.Set(option.Identity, &workers)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer workers.Done()
+			for d := range msgs {
+				if limiter != nil {
+					if err := limiter.Wait(ctx); err != nil {
+						return
 					}
 				}
-				message = map[string]interface{}{
-					"Identity": option.Identity,
-					"Queue":    option.Queue,
-					"Url":      option.Url,
-					"Secret":   option.Secret,
-					"Body":     bodyRecord,
-					"Status":   true,
-					"Response": responseRecord,
-					"Time":     time.Now().Unix(),
-				}
-				d.Ack(false)
+				c.process(option, d)
 			}
-			c.logging.Push(&types.LoggingPush{
-				Identity: option.Identity,
-				HasError: len(errs) != 0,
-				Message:  message,
-			})
-		}
-	}()
+		}()
+	}
 	return
 }
\ No newline at end of file
@@ -0,0 +1,69 @@
+package mq
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+func TestRetryCount(t *testing.T) {
+	cases := []struct {
+		name    string
+		headers amqp.Table
+		want    int64
+	}{
+		{"no x-death header", amqp.Table{}, 0},
+		{"empty x-death", amqp.Table{"x-death": []interface{}{}}, 0},
+		{"single death", amqp.Table{"x-death": []interface{}{amqp.Table{"count": int64(3)}}}, 3},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := retryCount(tc.headers); got != tc.want {
+				t.Errorf("retryCount() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	cases := []struct {
+		name    string
+		base    int64
+		attempt int64
+	}{
+		{"zero base falls back to default", 0, 0},
+		{"small attempt", 1000, 3},
+		{"large attempt does not overflow or go negative", 1000, 62},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := strconv.ParseInt(retryBackoff(tc.base, tc.attempt), 10, 64)
+			if err != nil {
+				t.Fatalf("retryBackoff() returned non-numeric expiration: %v", err)
+			}
+			if got < 0 {
+				t.Fatalf("retryBackoff() = %d, want non-negative", got)
+			}
+			if got > retryBackoffMax {
+				t.Fatalf("retryBackoff() = %d, want capped at %d", got, retryBackoffMax)
+			}
+		})
+	}
+}
+
+func TestAmqpDriveBackoff(t *testing.T) {
+	c := &AmqpDrive{
+		backoffBase:       time.Millisecond,
+		backoffMax:        10 * time.Millisecond,
+		backoffMultiplier: 2.0,
+		rand:              rand.New(rand.NewSource(1)),
+	}
+	for attempt := 0; attempt < 10; attempt++ {
+		if delay := c.backoff(attempt); delay > c.backoffMax {
+			t.Fatalf("backoff(%d) = %s, want capped at %s", attempt, delay, c.backoffMax)
+		}
+	}
+}
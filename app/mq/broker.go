@@ -0,0 +1,78 @@
+package mq
+
+import (
+	"fmt"
+	"net/url"
+
+	"mq-subscriber/app/logging"
+	"mq-subscriber/app/schema"
+	"mq-subscriber/app/types"
+)
+
+type Broker interface {
+	SetChannel(ID string) error
+	SetConsume(option types.SubscriberOption) error
+	CloseChannel(ID string) error
+	Delete(ID string) error
+	Health() bool
+}
+
+type Delivering interface {
+	SetDeliverers(deliverers map[string]types.Deliverer)
+}
+
+type Closer interface {
+	Close() error
+}
+
+type Driver func(rawURL string, schema *schema.Schema, logging *logging.Logging) (Broker, error)
+
+var drivers = make(map[string]Driver)
+
+func Register(scheme string, driver Driver) {
+	drivers[scheme] = driver
+}
+
+func New(rawURL string, schema *schema.Schema, logging *logging.Logging) (Broker, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	driver, ok := drivers[parsed.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("mq: no broker registered for scheme %q", parsed.Scheme)
+	}
+	return driver(rawURL, schema, logging)
+}
+
+func init() {
+	Register("amqp", func(rawURL string, schema *schema.Schema, logging *logging.Logging) (Broker, error) {
+		drive, err := NewAmqpDrive(rawURL, schema, logging)
+		if err != nil {
+			return nil, err
+		}
+		return drive, nil
+	})
+	Register("amqps", drivers["amqp"])
+	Register("nats", func(rawURL string, schema *schema.Schema, logging *logging.Logging) (Broker, error) {
+		drive, err := NewNatsDrive(rawURL, schema, logging)
+		if err != nil {
+			return nil, err
+		}
+		return drive, nil
+	})
+	Register("mqtt", func(rawURL string, schema *schema.Schema, logging *logging.Logging) (Broker, error) {
+		drive, err := NewMqttDrive(rawURL, schema, logging)
+		if err != nil {
+			return nil, err
+		}
+		return drive, nil
+	})
+	Register("kafka", func(rawURL string, schema *schema.Schema, logging *logging.Logging) (Broker, error) {
+		drive, err := NewKafkaDrive(rawURL, schema, logging)
+		if err != nil {
+			return nil, err
+		}
+		return drive, nil
+	})
+}
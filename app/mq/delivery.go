@@ -0,0 +1,44 @@
+package mq
+
+import (
+	"fmt"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"mq-subscriber/app/logging"
+	"mq-subscriber/app/types"
+)
+
+func pickDeliverer(deliverers map[string]types.Deliverer, transport string) types.Deliverer {
+	if deliverer, ok := deliverers[transport]; ok {
+		return deliverer
+	}
+	return deliverers[""]
+}
+
+func deliverAndLog(deliverer types.Deliverer, logger *logging.Logging, option types.SubscriberOption, payload []byte) {
+	var body []byte
+	var errs []error
+	if deliverer != nil {
+		body, errs = deliverer.Deliver(option, payload)
+	} else {
+		errs = []error{fmt.Errorf("mq: no deliverer registered for transport %q", option.Transport)}
+	}
+	var bodyRecord interface{}
+	jsoniter.Unmarshal(payload, &bodyRecord)
+	var responseRecord interface{}
+	jsoniter.Unmarshal(body, &responseRecord)
+	logger.Push(&types.LoggingPush{
+		Identity: option.Identity,
+		HasError: len(errs) != 0,
+		Message: map[string]interface{}{
+			"Identity": option.Identity,
+			"Queue":    option.Queue,
+			"Url":      option.Url,
+			"Body":     bodyRecord,
+			"Response": responseRecord,
+			"Time":     time.Now().Unix(),
+		},
+	})
+}
@@ -0,0 +1,78 @@
+package mq
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+type PublishOption struct {
+	Exchange    string
+	RoutingKey  string
+	ContentType string
+	Headers     map[string]interface{}
+	Body        []byte
+	Mandatory   bool
+	Immediate   bool
+}
+
+type Emitting interface {
+	Emitter(confirmMode bool) (*Emitter, error)
+}
+
+type Emitter struct {
+	channel     *amqp.Channel
+	confirmMode bool
+	confirms    chan amqp.Confirmation
+}
+
+func NewEmitter(conn *amqp.Connection, confirmMode bool) (*Emitter, error) {
+	channel, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	emitter := &Emitter{channel: channel, confirmMode: confirmMode}
+	if confirmMode {
+		if err := channel.Confirm(false); err != nil {
+			return nil, err
+		}
+		emitter.confirms = channel.NotifyPublish(make(chan amqp.Confirmation, 1))
+	}
+	return emitter, nil
+}
+
+func (e *Emitter) Publish(option PublishOption, timeout time.Duration) error {
+	headers := amqp.Table{}
+	for key, value := range option.Headers {
+		headers[key] = value
+	}
+	err := e.channel.Publish(option.Exchange, option.RoutingKey, option.Mandatory, option.Immediate, amqp.Publishing{
+		ContentType: option.ContentType,
+		Headers:     headers,
+		Body:        option.Body,
+	})
+	if err != nil {
+		return err
+	}
+	if !e.confirmMode {
+		return nil
+	}
+	select {
+	case confirmation := <-e.confirms:
+		if !confirmation.Ack {
+			return fmt.Errorf("mq: publish was nacked by broker")
+		}
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("mq: publish confirmation timed out after %s", timeout)
+	}
+}
+
+func (e *Emitter) Close() error {
+	return e.channel.Close()
+}
+
+func (c *AmqpDrive) Emitter(confirmMode bool) (*Emitter, error) {
+	return NewEmitter(c.conn, confirmMode)
+}
@@ -0,0 +1,158 @@
+package mq
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+
+	"mq-subscriber/app/logging"
+	"mq-subscriber/app/schema"
+	"mq-subscriber/app/types"
+)
+
+type KafkaDrive struct {
+	brokers []string
+	schema  *schema.Schema
+	logging *logging.Logging
+	mutex   sync.RWMutex
+	readers map[string]*kafka.Reader
+	cancels map[string]context.CancelFunc
+
+	healthMutex sync.RWMutex
+	healthy     bool
+
+	deliverersMutex sync.RWMutex
+	deliverers      map[string]types.Deliverer
+}
+
+func NewKafkaDrive(url string, schema *schema.Schema, logging *logging.Logging) (*KafkaDrive, error) {
+	return &KafkaDrive{
+		brokers:    []string{url},
+		schema:     schema,
+		logging:    logging,
+		readers:    make(map[string]*kafka.Reader),
+		cancels:    make(map[string]context.CancelFunc),
+		healthy:    true,
+		deliverers: make(map[string]types.Deliverer),
+	}, nil
+}
+
+func (c *KafkaDrive) SetDeliverers(deliverers map[string]types.Deliverer) {
+	c.deliverersMutex.Lock()
+	defer c.deliverersMutex.Unlock()
+	c.deliverers = deliverers
+}
+
+func (c *KafkaDrive) deliverer(transport string) types.Deliverer {
+	c.deliverersMutex.RLock()
+	defer c.deliverersMutex.RUnlock()
+	return pickDeliverer(c.deliverers, transport)
+}
+
+func (c *KafkaDrive) setHealthy(healthy bool) {
+	c.healthMutex.Lock()
+	defer c.healthMutex.Unlock()
+	c.healthy = healthy
+}
+
+func (c *KafkaDrive) backoff(attempt int) time.Duration {
+	delay := float64(defaultBackoffBase) * math.Pow(defaultBackoffMultiplier, float64(attempt))
+	if max := float64(defaultBackoffMax); delay > max {
+		delay = max
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+func (c *KafkaDrive) SetChannel(ID string) error {
+	return nil
+}
+
+func (c *KafkaDrive) SetConsume(option types.SubscriberOption) error {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: c.brokers,
+		Topic:   option.Queue,
+		GroupID: option.Identity,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	c.mutex.Lock()
+	c.readers[option.Identity] = reader
+	c.cancels[option.Identity] = cancel
+	c.mutex.Unlock()
+	go func() {
+		attempt := 0
+		for {
+			msg, err := reader.ReadMessage(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.setHealthy(false)
+				logrus.Error("kafka: failed to read message:", err)
+				select {
+				case <-time.After(c.backoff(attempt)):
+				case <-ctx.Done():
+					return
+				}
+				attempt++
+				continue
+			}
+			attempt = 0
+			c.setHealthy(true)
+			deliverAndLog(c.deliverer(option.Transport), c.logging, option, msg.Value)
+		}
+	}()
+	return nil
+}
+
+func (c *KafkaDrive) CloseChannel(ID string) error {
+	c.mutex.RLock()
+	cancel, hasCancel := c.cancels[ID]
+	reader, hasReader := c.readers[ID]
+	c.mutex.RUnlock()
+	if hasCancel {
+		cancel()
+	}
+	if !hasReader {
+		return nil
+	}
+	return reader.Close()
+}
+
+func (c *KafkaDrive) Delete(ID string) error {
+	err := c.CloseChannel(ID)
+	c.mutex.Lock()
+	delete(c.readers, ID)
+	delete(c.cancels, ID)
+	c.mutex.Unlock()
+	return err
+}
+
+func (c *KafkaDrive) Health() bool {
+	c.healthMutex.RLock()
+	defer c.healthMutex.RUnlock()
+	return c.healthy
+}
+
+func (c *KafkaDrive) Close() error {
+	c.mutex.RLock()
+	readers := make([]*kafka.Reader, 0, len(c.readers))
+	for _, reader := range c.readers {
+		readers = append(readers, reader)
+	}
+	c.mutex.RUnlock()
+	var lastErr error
+	for _, reader := range readers {
+		if err := reader.Close(); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,95 @@
+package mq
+
+import (
+	"sync"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"mq-subscriber/app/logging"
+	"mq-subscriber/app/schema"
+	"mq-subscriber/app/types"
+)
+
+type MqttDrive struct {
+	client  mqtt.Client
+	schema  *schema.Schema
+	logging *logging.Logging
+	mutex   sync.RWMutex
+	topics  map[string]string
+
+	deliverersMutex sync.RWMutex
+	deliverers      map[string]types.Deliverer
+}
+
+func NewMqttDrive(url string, schema *schema.Schema, logging *logging.Logging) (*MqttDrive, error) {
+	opts := mqtt.NewClientOptions().AddBroker(url)
+	client := mqtt.NewClient(opts)
+	if token := client.Connect(); token.Wait() && token.Error() != nil {
+		return nil, token.Error()
+	}
+	return &MqttDrive{
+		client:     client,
+		schema:     schema,
+		logging:    logging,
+		topics:     make(map[string]string),
+		deliverers: make(map[string]types.Deliverer),
+	}, nil
+}
+
+func (c *MqttDrive) SetDeliverers(deliverers map[string]types.Deliverer) {
+	c.deliverersMutex.Lock()
+	defer c.deliverersMutex.Unlock()
+	c.deliverers = deliverers
+}
+
+func (c *MqttDrive) deliverer(transport string) types.Deliverer {
+	c.deliverersMutex.RLock()
+	defer c.deliverersMutex.RUnlock()
+	return pickDeliverer(c.deliverers, transport)
+}
+
+func (c *MqttDrive) SetChannel(ID string) error {
+	return nil
+}
+
+func (c *MqttDrive) SetConsume(option types.SubscriberOption) error {
+	token := c.client.Subscribe(option.Queue, 1, func(client mqtt.Client, msg mqtt.Message) {
+		deliverAndLog(c.deliverer(option.Transport), c.logging, option, msg.Payload())
+	})
+	if token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+	c.mutex.Lock()
+	c.topics[option.Identity] = option.Queue
+	c.mutex.Unlock()
+	return nil
+}
+
+func (c *MqttDrive) CloseChannel(ID string) error {
+	c.mutex.RLock()
+	topic, ok := c.topics[ID]
+	c.mutex.RUnlock()
+	if !ok {
+		return nil
+	}
+	token := c.client.Unsubscribe(topic)
+	token.Wait()
+	return token.Error()
+}
+
+func (c *MqttDrive) Delete(ID string) error {
+	err := c.CloseChannel(ID)
+	c.mutex.Lock()
+	delete(c.topics, ID)
+	c.mutex.Unlock()
+	return err
+}
+
+func (c *MqttDrive) Health() bool {
+	return c.client != nil && c.client.IsConnected()
+}
+
+func (c *MqttDrive) Close() error {
+	c.client.Disconnect(250)
+	return nil
+}
@@ -0,0 +1,96 @@
+package mq
+
+import (
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+
+	"mq-subscriber/app/logging"
+	"mq-subscriber/app/schema"
+	"mq-subscriber/app/types"
+)
+
+type NatsDrive struct {
+	conn    *nats.Conn
+	schema  *schema.Schema
+	logging *logging.Logging
+	mutex   sync.RWMutex
+	subs    map[string]*nats.Subscription
+
+	deliverersMutex sync.RWMutex
+	deliverers      map[string]types.Deliverer
+}
+
+func NewNatsDrive(url string, schema *schema.Schema, logging *logging.Logging) (*NatsDrive, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+	return &NatsDrive{
+		conn:       conn,
+		schema:     schema,
+		logging:    logging,
+		subs:       make(map[string]*nats.Subscription),
+		deliverers: make(map[string]types.Deliverer),
+	}, nil
+}
+
+func (c *NatsDrive) SetDeliverers(deliverers map[string]types.Deliverer) {
+	c.deliverersMutex.Lock()
+	defer c.deliverersMutex.Unlock()
+	c.deliverers = deliverers
+}
+
+func (c *NatsDrive) deliverer(transport string) types.Deliverer {
+	c.deliverersMutex.RLock()
+	defer c.deliverersMutex.RUnlock()
+	return pickDeliverer(c.deliverers, transport)
+}
+
+func (c *NatsDrive) SetChannel(ID string) error {
+	return nil
+}
+
+func (c *NatsDrive) SetConsume(option types.SubscriberOption) error {
+	sub, err := c.conn.Subscribe(option.Queue, func(msg *nats.Msg) {
+		deliverAndLog(c.deliverer(option.Transport), c.logging, option, msg.Data)
+	})
+	if err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	c.subs[option.Identity] = sub
+	c.mutex.Unlock()
+	return nil
+}
+
+func (c *NatsDrive) CloseChannel(ID string) error {
+	c.mutex.RLock()
+	sub, ok := c.subs[ID]
+	c.mutex.RUnlock()
+	if !ok {
+		return nil
+	}
+	return sub.Unsubscribe()
+}
+
+func (c *NatsDrive) Delete(ID string) error {
+	err := c.CloseChannel(ID)
+	c.mutex.Lock()
+	delete(c.subs, ID)
+	c.mutex.Unlock()
+	if err != nil {
+		logrus.Error("nats: failed to unsubscribe:", err)
+	}
+	return nil
+}
+
+func (c *NatsDrive) Health() bool {
+	return c.conn != nil && c.conn.IsConnected()
+}
+
+func (c *NatsDrive) Close() error {
+	c.conn.Close()
+	return nil
+}
@@ -0,0 +1,65 @@
+package schema
+
+import (
+	"fmt"
+	"sync"
+
+	"mq-subscriber/app/types"
+)
+
+type Schema struct {
+	mutex   sync.RWMutex
+	options map[string]types.SubscriberOption
+}
+
+func NewSchema() *Schema {
+	return &Schema{
+		options: make(map[string]types.SubscriberOption),
+	}
+}
+
+func (s *Schema) Set(option types.SubscriberOption) error {
+	if err := validateTransport(option); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.options[option.Identity] = option
+	return nil
+}
+
+func validateTransport(option types.SubscriberOption) error {
+	switch option.Transport {
+	case "", "http":
+		if option.Url == "" {
+			return fmt.Errorf("schema: http transport requires Url")
+		}
+	case "grpc":
+		if option.GRPCTarget == "" || option.GRPCMethod == "" {
+			return fmt.Errorf("schema: grpc transport requires GRPCTarget and GRPCMethod")
+		}
+	case "amqp":
+		if option.RepublishExchange == "" {
+			return fmt.Errorf("schema: amqp transport requires RepublishExchange")
+		}
+	default:
+		return fmt.Errorf("schema: unknown transport %q", option.Transport)
+	}
+	return nil
+}
+
+func (s *Schema) Get(ID string) (types.SubscriberOption, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	option, ok := s.options[ID]
+	if !ok {
+		return option, fmt.Errorf("schema: no subscriber option registered for %q", ID)
+	}
+	return option, nil
+}
+
+func (s *Schema) Delete(ID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.options, ID)
+}
@@ -0,0 +1,5 @@
+package types
+
+type Deliverer interface {
+	Deliver(option SubscriberOption, body []byte) ([]byte, []error)
+}
@@ -0,0 +1,7 @@
+package types
+
+type FetchOption struct {
+	Url    string
+	Secret string
+	Body   string
+}
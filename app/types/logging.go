@@ -0,0 +1,7 @@
+package types
+
+type LoggingPush struct {
+	Identity string
+	HasError bool
+	Message  interface{}
+}
@@ -0,0 +1,24 @@
+package types
+
+type SubscriberOption struct {
+	Identity string
+	Source   string
+	Queue    string
+	Url      string
+	Secret   string
+
+	DeadLetterExchange   string
+	DeadLetterRoutingKey string
+	DeadLetterTTL        int64
+	MaxRetries           int
+
+	Transport           string
+	GRPCTarget          string
+	GRPCMethod          string
+	RepublishExchange   string
+	RepublishRoutingKey string
+
+	PrefetchCount int
+	Concurrency   int
+	RatePerSecond int
+}
@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/streadway/amqp"
+)
+
+type SyncChannel struct {
+	mutex    sync.RWMutex
+	channels map[string]*amqp.Channel
+}
+
+func NewSyncChannel() *SyncChannel {
+	return &SyncChannel{channels: make(map[string]*amqp.Channel)}
+}
+
+func (s *SyncChannel) Set(ID string, channel *amqp.Channel) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.channels[ID] = channel
+}
+
+func (s *SyncChannel) Get(ID string) *amqp.Channel {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.channels[ID]
+}
+
+func (s *SyncChannel) Delete(ID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.channels, ID)
+}
+
+type SyncChannelDone struct {
+	mutex sync.RWMutex
+	done  map[string]chan int
+}
+
+func NewSyncChannelDone() *SyncChannelDone {
+	return &SyncChannelDone{done: make(map[string]chan int)}
+}
+
+func (s *SyncChannelDone) Set(ID string, done chan int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.done[ID] = done
+}
+
+func (s *SyncChannelDone) Get(ID string) chan int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.done[ID]
+}
+
+func (s *SyncChannelDone) Delete(ID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.done, ID)
+}
+
+type SyncChannelReady struct {
+	mutex sync.RWMutex
+	ready map[string]bool
+}
+
+func NewSyncChannelReady() *SyncChannelReady {
+	return &SyncChannelReady{ready: make(map[string]bool)}
+}
+
+func (s *SyncChannelReady) Set(ID string, ready bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.ready[ID] = ready
+}
+
+func (s *SyncChannelReady) Get(ID string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.ready[ID]
+}
+
+func (s *SyncChannelReady) Delete(ID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.ready, ID)
+}
+
+type SyncNotifyChanClose struct {
+	mutex  sync.RWMutex
+	notify map[string]chan *amqp.Error
+}
+
+func NewSyncNotifyChanClose() *SyncNotifyChanClose {
+	return &SyncNotifyChanClose{notify: make(map[string]chan *amqp.Error)}
+}
+
+func (s *SyncNotifyChanClose) Set(ID string, notify chan *amqp.Error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.notify[ID] = notify
+}
+
+func (s *SyncNotifyChanClose) Get(ID string) chan *amqp.Error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.notify[ID]
+}
+
+func (s *SyncNotifyChanClose) Delete(ID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.notify, ID)
+}
+
+type SyncWaitGroup struct {
+	mutex  sync.RWMutex
+	groups map[string]*sync.WaitGroup
+}
+
+func NewSyncWaitGroup() *SyncWaitGroup {
+	return &SyncWaitGroup{groups: make(map[string]*sync.WaitGroup)}
+}
+
+func (s *SyncWaitGroup) Set(ID string, group *sync.WaitGroup) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.groups[ID] = group
+}
+
+func (s *SyncWaitGroup) Get(ID string) *sync.WaitGroup {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.groups[ID]
+}
+
+func (s *SyncWaitGroup) Delete(ID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.groups, ID)
+}
+
+type SyncCancel struct {
+	mutex   sync.RWMutex
+	cancels map[string]context.CancelFunc
+}
+
+func NewSyncCancel() *SyncCancel {
+	return &SyncCancel{cancels: make(map[string]context.CancelFunc)}
+}
+
+func (s *SyncCancel) Set(ID string, cancel context.CancelFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cancels[ID] = cancel
+}
+
+func (s *SyncCancel) Get(ID string) context.CancelFunc {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.cancels[ID]
+}
+
+func (s *SyncCancel) Delete(ID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.cancels, ID)
+}